@@ -0,0 +1,165 @@
+package json
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/itchyny/gojq"
+)
+
+// Gojq implements Processor by parsing and running jq programs in-process
+// via gojq, rather than shelling out to a jq binary. This avoids the
+// dependency on jq being installed, at the cost of gojq's slightly
+// different feature set (it's a reimplementation, not a binding).
+type Gojq struct {
+	Debug io.Writer
+
+	mu      sync.Mutex
+	compact bool
+	raw     bool
+}
+
+// NewGojq returns a new gojq-backed Processor with all configurations
+// applied.
+func NewGojq(opts ...GojqOption) (*Gojq, error) {
+	gq := &Gojq{}
+
+	var err error
+	for _, opt := range opts {
+		if gq, err = opt(gq); err != nil {
+			return gq, err
+		}
+	}
+
+	return gq, nil
+}
+
+// Process parses source as JSON and runs program against it with gojq. It is
+// equivalent to ProcessContext with a context.Background().
+func (gq *Gojq) Process(source io.Reader, program string) (io.Reader, error) {
+	return gq.ProcessContext(context.Background(), source, program)
+}
+
+// ProcessContext compiles program once, then decodes source as a stream of
+// JSON values and runs the compiled program against each, checking ctx
+// between results so an in-flight run stops promptly once it's cancelled.
+// Errors are classified as ErrKindCancelled, ErrKindParse (the program
+// failed to parse/compile, or raised an error while running), or
+// ErrKindFatal (source wasn't valid JSON, or a result couldn't be encoded).
+func (gq *Gojq) ProcessContext(ctx context.Context, source io.Reader, program string) (io.Reader, error) {
+	gq.debugf("processing program: %s\n", program)
+
+	query, err := gojq.Parse(program)
+	if err != nil {
+		return nil, &ProcessError{Kind: ErrKindParse, Err: err}
+	}
+
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, &ProcessError{Kind: ErrKindParse, Err: err}
+	}
+
+	gq.mu.Lock()
+	compact, raw := gq.compact, gq.raw
+	gq.mu.Unlock()
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	if !compact {
+		enc.SetIndent("", "  ")
+	}
+
+	dec := json.NewDecoder(source)
+	dec.UseNumber()
+
+	for {
+		var input interface{}
+		if err := dec.Decode(&input); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, &ProcessError{Kind: ErrKindFatal, Err: fmt.Errorf("cannot read json source data: %v", err)}
+		}
+
+		iter := code.RunWithContext(ctx, input)
+		for {
+			v, ok := iter.Next()
+			if !ok {
+				break
+			}
+			if err, ok := v.(error); ok {
+				if ctx.Err() != nil {
+					return nil, &ProcessError{Kind: ErrKindCancelled, Err: ctx.Err()}
+				}
+				return nil, &ProcessError{Kind: ErrKindParse, Err: err}
+			}
+
+			if s, ok := v.(string); ok && raw {
+				out.WriteString(s)
+				out.WriteByte('\n')
+				continue
+			}
+			if err := enc.Encode(v); err != nil {
+				return nil, &ProcessError{Kind: ErrKindFatal, Err: fmt.Errorf("cannot encode result: %v", err)}
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil, &ProcessError{Kind: ErrKindCancelled, Err: ctx.Err()}
+		}
+	}
+
+	gq.debugf("program result:\n")
+	gq.debugf(out.String())
+
+	return bytes.NewReader(out.Bytes()), nil
+}
+
+// GojqOption allows a client to configure the behavior of the Gojq
+// processor.
+type GojqOption func(*Gojq) (*Gojq, error)
+
+// GojqOptionCompact tells Gojq to return compact output.
+func GojqOptionCompact(compact bool) GojqOption {
+	return func(gq *Gojq) (*Gojq, error) {
+		gq.compact = compact
+		return gq, nil
+	}
+}
+
+// GojqOptionRaw tells Gojq to return raw string output.
+func GojqOptionRaw(raw bool) GojqOption {
+	return func(gq *Gojq) (*Gojq, error) {
+		gq.raw = raw
+		return gq, nil
+	}
+}
+
+// ToggleCompact flips the internal compact option. It's safe to call while a
+// ProcessContext run is in flight on another goroutine.
+func (gq *Gojq) ToggleCompact() {
+	gq.mu.Lock()
+	defer gq.mu.Unlock()
+
+	gq.debugf("setting compact to %b\n", !gq.compact)
+	gq.compact = !gq.compact
+}
+
+// ToggleRaw flips the internal raw option. It's safe to call while a
+// ProcessContext run is in flight on another goroutine.
+func (gq *Gojq) ToggleRaw() {
+	gq.mu.Lock()
+	defer gq.mu.Unlock()
+
+	gq.debugf("setting raw to %b\n", !gq.raw)
+	gq.raw = !gq.raw
+}
+
+func (gq *Gojq) debugf(format string, args ...interface{}) {
+	if gq.Debug != nil {
+		fmt.Fprintf(gq.Debug, "[Gojq] "+format, args...)
+	}
+}
@@ -3,9 +3,55 @@
 // result.
 package json
 
-import "io"
+import (
+	"context"
+	"io"
+)
 
 // Processor runs a set of commands over some input JSON and outputs the result
 type Processor interface {
+	// Process runs program against source and returns the result. It is
+	// equivalent to ProcessContext with a context.Background().
 	Process(source io.Reader, program string) (io.Reader, error)
+
+	// ProcessContext runs program against source and returns the result.
+	// Implementations should stop the underlying invocation promptly when
+	// ctx is cancelled and report it via a *ProcessError of ErrKindCancelled.
+	ProcessContext(ctx context.Context, source io.Reader, program string) (io.Reader, error)
+
+	// ToggleCompact flips whether results are printed compact or pretty.
+	ToggleCompact()
+	// ToggleRaw flips whether string results are printed raw or quoted.
+	ToggleRaw()
+}
+
+// ErrKind classifies why a Processor's run failed, so callers can decide
+// whether to surface the error, ignore it, or abort.
+type ErrKind int
+
+const (
+	// ErrKindFatal indicates a failure unrelated to the jq program itself,
+	// e.g. the jq binary is missing or the input couldn't be read.
+	ErrKindFatal ErrKind = iota
+	// ErrKindCancelled indicates the run was superseded by a newer one and
+	// stopped via context cancellation; callers should typically ignore it.
+	ErrKindCancelled
+	// ErrKindParse indicates the jq program itself was invalid or failed
+	// against the input, e.g. a syntax error reported on stderr.
+	ErrKindParse
+)
+
+// ProcessError wraps a Process/ProcessContext failure with its ErrKind.
+type ProcessError struct {
+	Kind ErrKind
+	Err  error
+}
+
+func (e *ProcessError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap supports errors.Is/As against the wrapped error.
+func (e *ProcessError) Unwrap() error {
+	return e.Err
 }
@@ -2,16 +2,21 @@ package json
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os/exec"
+	"strings"
+	"sync"
 )
 
 // Shell makes calls to the jq binary installed in the current environment to
 // implement the Processor interface.
 type Shell struct {
-	Debug   io.Writer
+	Debug io.Writer
+
+	mu      sync.Mutex
 	compact bool
 	raw     bool
 }
@@ -30,35 +35,67 @@ func NewShell(opts ...ShellOption) (*Shell, error) {
 	return sh, nil
 }
 
-// Process runs the input JSON and the processing program through the jq command
-// with both as inputs via stdin. The results or a possible error are returned.
+// Process runs the input JSON and the processing program through the jq
+// command with both as inputs via stdin. The results or a possible error are
+// returned. It is equivalent to ProcessContext with a context.Background().
 func (sh *Shell) Process(source io.Reader, program string) (io.Reader, error) {
+	return sh.ProcessContext(context.Background(), source, program)
+}
+
+// ProcessContext runs program through the jq binary, forked via
+// exec.CommandContext so an in-flight run is killed as soon as ctx is
+// cancelled. Errors are classified as ErrKindCancelled, ErrKindParse (jq
+// exited non-zero, e.g. a program syntax error reported on stderr), or
+// ErrKindFatal (anything else, such as jq not being installed).
+func (sh *Shell) ProcessContext(ctx context.Context, source io.Reader, program string) (io.Reader, error) {
+	sh.mu.Lock()
+	compact, raw := sh.compact, sh.raw
+	sh.mu.Unlock()
+
 	var args []string
-	if sh.compact {
+	if compact {
 		args = append(args, "-c")
 	}
-	if sh.raw {
+	if raw {
 		args = append(args, "-r")
 	}
 
 	sh.debugf("processing program: %s\n", program)
 	args = append(args, program)
-	cmd := exec.Command("jq", args...)
+	cmd := exec.CommandContext(ctx, "jq", args...)
 
-	src, _ := ioutil.ReadAll(source)
+	src, err := ioutil.ReadAll(source)
+	if err != nil {
+		return nil, &ProcessError{Kind: ErrKindFatal, Err: fmt.Errorf("cannot read json source data: %v", err)}
+	}
 	sh.debugf("file input:\n")
 	sh.debugf(string(src))
 
 	cmd.Stdin = bytes.NewReader(src)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.Canceled {
+			return nil, &ProcessError{Kind: ErrKindCancelled, Err: ctx.Err()}
+		}
+
 		sh.debugf("processing error: %v\n", err)
-		return nil, fmt.Errorf("cannot read jq output: %v", err)
+		if _, ok := err.(*exec.ExitError); ok {
+			return nil, &ProcessError{
+				Kind: ErrKindParse,
+				Err:  fmt.Errorf("%s", strings.TrimSpace(stderr.String())),
+			}
+		}
+		return nil, &ProcessError{Kind: ErrKindFatal, Err: fmt.Errorf("cannot run jq: %v", err)}
 	}
+
 	sh.debugf("program result:\n")
-	sh.debugf(string(out))
+	sh.debugf(stdout.String())
 
-	return bytes.NewReader(out), nil
+	return bytes.NewReader(stdout.Bytes()), nil
 }
 
 // ShellOption allows a client to configure the behavior of the underlying jq
@@ -81,16 +118,24 @@ func OptionRaw(raw bool) ShellOption {
 	}
 }
 
-// ToggleCompact flips the internal compact option
+// ToggleCompact flips the internal compact option. It's safe to call while a
+// ProcessContext run is in flight on another goroutine.
 func (sh *Shell) ToggleCompact() {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
 	sh.debugf("setting compact to %b\n", !sh.compact)
 	sh.compact = !sh.compact
 }
 
-// ToggleRaw flips the internal raw option
+// ToggleRaw flips the internal raw option. It's safe to call while a
+// ProcessContext run is in flight on another goroutine.
 func (sh *Shell) ToggleRaw() {
-	sh.debugf("setting compact to %b\n", !sh.compact)
-	sh.compact = !sh.compact
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	sh.debugf("setting raw to %b\n", !sh.raw)
+	sh.raw = !sh.raw
 }
 
 func (sh *Shell) debugf(format string, args ...interface{}) {
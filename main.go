@@ -2,22 +2,40 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"gitlab.com/thedahv/jq-live/json"
 	"gitlab.com/thedahv/jq-live/ui"
 )
 
+// debounceDelay is how long the event loop waits after the last keystroke
+// before re-running the jq program, so a fast typist doesn't fork a process
+// per rune.
+const debounceDelay = 120 * time.Millisecond
+
+// processResult carries a Processor run back to the event loop once it
+// completes, whether it succeeded, failed, or was cancelled.
+type processResult struct {
+	out io.Reader
+	err error
+}
+
 func main() {
 	var (
 		compact   = flag.Bool("c", false, "compact output")
 		debug     = flag.String("debug", "", "path to write debug information")
 		raw       = flag.Bool("r", false, "raw output")
+		uiBackend = flag.String("ui", "termbox", "display backend to use: termbox or tcell")
+		engine    = flag.String("engine", "gojq", "processing engine to use: gojq or shell")
 		debugFile *os.File
 		source    io.Reader
 		jsonData  []byte
@@ -72,11 +90,7 @@ func main() {
 		}
 	}
 
-	processor, err := json.NewShell(
-		json.OptionCompact(*compact),
-		json.OptionRaw(*raw),
-	)
-	processor.Debug = debugFile
+	processor, err := newProcessor(*engine, debugFile, *compact, *raw)
 	if err != nil {
 		log.Fatalf("unable to start up processor: %v", err)
 	}
@@ -91,142 +105,386 @@ func main() {
 		log.Fatalf("unable to process JSON: %v", err)
 	}
 
-	display := &ui.Termbox{Debug: debugFile}
+	display, err := newDisplay(*uiBackend, debugFile)
+	if err != nil {
+		log.Fatalf("unable to select display: %v", err)
+	}
 	if err := display.Start(program); err != nil {
 		log.Fatalf("cannot start up display: %v", err)
 	}
 
+	var (
+		results = make(chan processResult)
+		cancel  context.CancelFunc
+
+		debounce = time.NewTimer(debounceDelay)
+
+		compactMode = *compact
+		rawMode     = *raw
+		lastErr     string
+
+		saveSidecar     bool
+		pendingSavePath string
+	)
+	debounce.Stop()
+
+	// setStatus refreshes the footer row from the event loop's view of the
+	// processor mode and the last error, if any.
+	setStatus := func() {
+		display.SetStatus(ui.Status{
+			Compact:     compactMode,
+			Raw:         rawMode,
+			LastError:   lastErr,
+			SaveSidecar: saveSidecar,
+		})
+		display.RenderStatus()
+	}
+
 	// Initial draw
 	display.RenderInput()
 	if err := display.RenderResults(out); err != nil {
 		log.Fatalf("cannot render result: %v", err)
 	}
+	setStatus()
+
+	// run cancels whatever run is in flight and kicks off a new one for
+	// program, delivering its outcome to results once it completes.
+	run := func(program string) {
+		if cancel != nil {
+			cancel()
+		}
+
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(context.Background())
+
+		go func() {
+			out, err := processor.ProcessContext(ctx, bytes.NewReader(jsonData), program)
+			results <- processResult{out: out, err: err}
+		}()
+	}
+
+	scheduleRun := func() {
+		if !debounce.Stop() {
+			select {
+			case <-debounce.C:
+			default:
+			}
+		}
+		debounce.Reset(debounceDelay)
+	}
 
 	// The UI display will emit action events on the channel representing actions
 	// the application can take. Each can possibly be associated with an action to
 	// update the internal state, followed by a render step.
 	for {
-		switch action := <-display.Events(); action {
-		case ui.ActionInputBackspace:
-			display.UpdateInputBackspace()
-			display.RenderInput()
-
-		case ui.ActionExit:
-			display.Quit()
-			os.Exit(0)
-
-		case ui.ActionInput:
-			display.UpdateInput()
-			display.RenderInput()
-
-		case ui.ActionPrint:
-			display.Quit()
-			out, err := processor.Process(bytes.NewReader(jsonData), display.Input)
-			if err != nil {
-				// TODO distinguish between normal parse errors and crashable errors
-				if debugFile != nil {
-					fmt.Fprintf(debugFile, "parse error: %v\n", err)
-					fmt.Fprintf(debugFile, "program: %s\n", display.Input)
+		select {
+		case res := <-results:
+			if res.err != nil {
+				logProcessError(debugFile, display.Program(), res.err)
+				if pe, ok := res.err.(*json.ProcessError); !ok || pe.Kind != json.ErrKindCancelled {
+					lastErr = res.err.Error()
+					setStatus()
 				}
-				os.Exit(1)
-			} else {
-				io.Copy(os.Stdout, out)
-				os.Exit(0)
+				continue
 			}
+			lastErr = ""
+			if err := display.RenderResults(res.out); err != nil {
+				log.Fatalf("cannot render result: %v", err)
+			}
+			setStatus()
 
-		case ui.ActionSaveInput:
-			display.UpdateSaveInput()
-			display.RenderFilePrompt()
+		case <-debounce.C:
+			run(display.Program())
 
-		case ui.ActionSavePrompt:
-			// TODO Support cancellation in save prompt
-			display.SaveInputMode = true
-			if err := display.RenderFilePrompt(); err != nil {
-				log.Fatalf("unable to open save form: %v", err)
-			}
+		case action := <-display.Events():
+			switch action {
+			case ui.ActionInputBackspace:
+				display.UpdateInputBackspace()
+				display.RenderInput()
+				scheduleRun()
 
-		case ui.ActionSavePromptBackspace:
-			display.UpdateSaveInputBackspace()
-			display.RenderFilePrompt()
-
-		case ui.ActionSaveSubmit:
-			display.Quit()
-			// TODO handle "mkdir -p" style directory create
-			f, err := os.OpenFile(
-				fmt.Sprintf("%s/%s", cwd, display.SavePath),
-				os.O_WRONLY|os.O_CREATE|os.O_TRUNC,
-				0666,
-			)
-			if err != nil {
-				log.Fatalf("could not open save file: %v", err)
-			}
-			out, err := processor.Process(bytes.NewReader(jsonData), display.Input)
-			if err != nil {
-				// TODO distinguish between normal parse errors and crashable errors
-				if debugFile != nil {
-					fmt.Fprintf(debugFile, "parse error: %v\n", err)
-					fmt.Fprintf(debugFile, "program: %s\n", display.Input)
-				}
-			} else {
-				_, err := io.Copy(f, out)
-				if err != nil {
-					log.Fatalf("could not write results to file: %v", err)
-				}
+			case ui.ActionExit:
+				display.Quit()
 				os.Exit(0)
-			}
 
-		case ui.ActionToggleCompact:
-			processor.ToggleCompact()
-			out, err := processor.Process(bytes.NewReader(jsonData), display.Input)
-			if err != nil {
-				// TODO distinguish between normal parse errors and crashable errors
-				if debugFile != nil {
-					fmt.Fprintf(debugFile, "parse error: %v\n", err)
-					fmt.Fprintf(debugFile, "program: %s\n", display.Input)
-				}
-			} else {
-				err := display.RenderResults(out)
+			case ui.ActionInput:
+				display.UpdateInput()
+				display.RenderInput()
+				scheduleRun()
+
+			case ui.ActionPrint:
+				display.Quit()
+				out, err := processor.Process(bytes.NewReader(jsonData), display.Program())
 				if err != nil {
-					log.Fatalf("cannot render result: %v", err)
+					logProcessError(debugFile, display.Program(), err)
+					os.Exit(1)
+				} else {
+					io.Copy(os.Stdout, out)
+					os.Exit(0)
 				}
-			}
 
-		case ui.ActionToggleRaw:
-			// TODO need some kind of UI indicator to indicate active options
-			processor.ToggleRaw()
-			out, err := processor.Process(bytes.NewReader(jsonData), display.Input)
-			if err != nil {
-				// TODO distinguish between normal parse errors and crashable errors
-				if debugFile != nil {
-					fmt.Fprintf(debugFile, "parse error: %v\n", err)
-					fmt.Fprintf(debugFile, "program: %s\n", display.Input)
+			case ui.ActionSaveInput:
+				display.UpdateSaveInput()
+				display.RenderFilePrompt()
+
+			case ui.ActionSavePrompt:
+				saveSidecar = false
+				pendingSavePath = ""
+				display.SetConfirmMode(false)
+				display.SetSaveMode(true)
+				if err := display.RenderFilePrompt(); err != nil {
+					log.Fatalf("unable to open save form: %v", err)
 				}
-			} else {
-				err := display.RenderResults(out)
+				setStatus()
+
+			case ui.ActionSavePromptBackspace:
+				display.UpdateSaveInputBackspace()
+				display.RenderFilePrompt()
+
+			case ui.ActionToggleSaveSidecar:
+				saveSidecar = !saveSidecar
+				setStatus()
+
+			case ui.ActionSaveCancel:
+				display.SetSaveMode(false)
+				display.RenderInput()
+				setStatus()
+
+			case ui.ActionSaveSubmit:
+				path, err := resolveSavePath(cwd, display.SavePath())
 				if err != nil {
-					log.Fatalf("cannot render result: %v", err)
+					lastErr = err.Error()
+					setStatus()
+					continue
 				}
-			}
 
-		case ui.ActionSubmit:
-			fmt.Fprintf(debugFile, "submitting program: %s\n", display.Input)
-			out, err := processor.Process(bytes.NewReader(jsonData), display.Input)
-			if err != nil {
-				// TODO distinguish between normal parse errors and crashable errors
-				if debugFile != nil {
-					fmt.Fprintf(debugFile, "parse error: %v\n", err)
-					fmt.Fprintf(debugFile, "program: %s\n", display.Input)
+				if _, err := os.Stat(path); err == nil {
+					pendingSavePath = path
+					display.SetConfirmMode(true)
+					if err := display.RenderConfirm(fmt.Sprintf("overwrite %s? (y/n)", path)); err != nil {
+						log.Fatalf("unable to render overwrite confirmation: %v", err)
+					}
+					continue
 				}
-			} else {
-				err := display.RenderResults(out)
-				if err != nil {
-					log.Fatalf("cannot render result: %v", err)
+
+				if err := writeResult(processor, jsonData, display.Program(), path, saveSidecar); err != nil {
+					lastErr = err.Error()
+					setStatus()
+					continue
+				}
+				display.Quit()
+				os.Exit(0)
+
+			case ui.ActionSaveConfirmYes:
+				display.SetConfirmMode(false)
+				if err := writeResult(processor, jsonData, display.Program(), pendingSavePath, saveSidecar); err != nil {
+					lastErr = err.Error()
+					display.RenderFilePrompt()
+					setStatus()
+					continue
 				}
+				display.Quit()
+				os.Exit(0)
+
+			case ui.ActionSaveConfirmNo:
+				pendingSavePath = ""
+				display.SetConfirmMode(false)
+				display.RenderFilePrompt()
+
+			case ui.ActionToggleCompact:
+				processor.ToggleCompact()
+				compactMode = !compactMode
+				setStatus()
+				run(display.Program())
+
+			case ui.ActionToggleRaw:
+				processor.ToggleRaw()
+				rawMode = !rawMode
+				setStatus()
+				run(display.Program())
+
+			case ui.ActionScrollDown:
+				display.ScrollDown()
+
+			case ui.ActionScrollUp:
+				display.ScrollUp()
+
+			case ui.ActionScrollPageDown:
+				display.ScrollPageDown()
+
+			case ui.ActionScrollPageUp:
+				display.ScrollPageUp()
+
+			case ui.ActionScrollTop:
+				display.ScrollTop()
+
+			case ui.ActionScrollBottom:
+				display.ScrollBottom()
+
+			case ui.ActionScrollLeft:
+				display.ScrollLeft()
+
+			case ui.ActionScrollRight:
+				display.ScrollRight()
+
+			case ui.ActionSubmit:
+				run(display.Program())
+
+			case ui.ActionCursorLeft:
+				display.CursorLeft()
+				display.RenderInput()
+
+			case ui.ActionCursorRight:
+				display.CursorRight()
+				display.RenderInput()
+
+			case ui.ActionWordLeft:
+				display.WordLeft()
+				display.RenderInput()
+
+			case ui.ActionWordRight:
+				display.WordRight()
+				display.RenderInput()
+
+			case ui.ActionHome:
+				display.Home()
+				display.RenderInput()
+
+			case ui.ActionEnd:
+				display.End()
+				display.RenderInput()
+
+			case ui.ActionDeleteForward:
+				display.DeleteForward()
+				display.RenderInput()
+				scheduleRun()
+
+			case ui.ActionKillToEnd:
+				display.KillToEnd()
+				display.RenderInput()
+				scheduleRun()
+
+			case ui.ActionYank:
+				display.Yank()
+				display.RenderInput()
+				scheduleRun()
+
+			case ui.ActionHistoryPrev:
+				display.HistoryPrev()
+				display.RenderInput()
+				scheduleRun()
+
+			case ui.ActionHistoryNext:
+				display.HistoryNext()
+				display.RenderInput()
+				scheduleRun()
 			}
 		}
 	}
 }
 
+// logProcessError records a Process/ProcessContext failure to the debug
+// file, if any. Cancelled runs are expected whenever a newer keystroke
+// supersedes them and aren't logged as errors.
+func logProcessError(debugFile *os.File, program string, err error) {
+	if pe, ok := err.(*json.ProcessError); ok && pe.Kind == json.ErrKindCancelled {
+		return
+	}
+	if debugFile != nil {
+		fmt.Fprintf(debugFile, "process error: %v\n", err)
+		fmt.Fprintf(debugFile, "program: %s\n", program)
+	}
+}
+
+// newProcessor constructs the Processor named by engine ("gojq" or "shell"),
+// applying the compact/raw options and debug file consistently across
+// either backend.
+func newProcessor(engine string, debugFile *os.File, compact, raw bool) (json.Processor, error) {
+	switch engine {
+	case "gojq", "":
+		gq, err := json.NewGojq(json.GojqOptionCompact(compact), json.GojqOptionRaw(raw))
+		if err != nil {
+			return nil, err
+		}
+		gq.Debug = debugFile
+		return gq, nil
+	case "shell":
+		sh, err := json.NewShell(json.OptionCompact(compact), json.OptionRaw(raw))
+		if err != nil {
+			return nil, err
+		}
+		sh.Debug = debugFile
+		return sh, nil
+	default:
+		return nil, fmt.Errorf("unknown engine %q (want gojq or shell)", engine)
+	}
+}
+
+// resolveSavePath turns the text entered in the save prompt into an absolute
+// path: a leading "~" is expanded to the user's home directory, an absolute
+// path is used as-is, and anything else is resolved relative to cwd.
+func resolveSavePath(cwd, input string) (string, error) {
+	if input == "~" || strings.HasPrefix(input, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("could not resolve ~: %v", err)
+		}
+		input = filepath.Join(home, strings.TrimPrefix(input, "~"))
+	}
+
+	if filepath.IsAbs(input) {
+		return input, nil
+	}
+	return filepath.Join(cwd, input), nil
+}
+
+// writeResult runs program over jsonData and writes it to path, creating any
+// missing parent directories first. When withSidecar is set, program itself
+// is also written alongside the output as a path+".jq" sidecar file.
+func writeResult(processor json.Processor, jsonData []byte, program, path string, withSidecar bool) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("could not create directory %s: %v", dir, err)
+		}
+	}
+
+	out, err := processor.Process(bytes.NewReader(jsonData), program)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		return fmt.Errorf("could not open save file: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, out); err != nil {
+		return fmt.Errorf("could not write results to file: %v", err)
+	}
+
+	if withSidecar {
+		if err := ioutil.WriteFile(path+".jq", []byte(program), 0666); err != nil {
+			return fmt.Errorf("could not write .jq sidecar: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// newDisplay constructs the Display backend named by uiBackend ("termbox" or
+// "tcell").
+func newDisplay(uiBackend string, debugFile *os.File) (ui.Display, error) {
+	switch uiBackend {
+	case "termbox", "":
+		return &ui.Termbox{Debug: debugFile}, nil
+	case "tcell":
+		return &ui.Tcell{Debug: debugFile}, nil
+	default:
+		return nil, fmt.Errorf("unknown ui backend %q (want termbox or tcell)", uiBackend)
+	}
+}
+
 func inputOnStdin(stdin *os.File) bool {
 	stat, err := stdin.Stat()
 	if err != nil {
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSavePath(t *testing.T) {
+	cwd := "/home/user/project"
+
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"relative path joins cwd", "out.json", filepath.Join(cwd, "out.json")},
+		{"relative path with subdirectory joins cwd", "sub/out.json", filepath.Join(cwd, "sub/out.json")},
+		{"absolute path is used as-is", "/tmp/out.json", "/tmp/out.json"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := resolveSavePath(cwd, tc.input)
+			if err != nil {
+				t.Fatalf("resolveSavePath(%q, %q) returned error: %v", cwd, tc.input, err)
+			}
+			if got != tc.want {
+				t.Errorf("resolveSavePath(%q, %q) = %q, want %q", cwd, tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveSavePathExpandsHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	got, err := resolveSavePath("/irrelevant/cwd", "~/out.json")
+	if err != nil {
+		t.Fatalf("resolveSavePath returned error: %v", err)
+	}
+	if want := filepath.Join(home, "out.json"); got != want {
+		t.Errorf("resolveSavePath(\"~/out.json\") = %q, want %q", got, want)
+	}
+}
+
+// fakeProcessor is a minimal json.Processor stub so writeResult's
+// filesystem behavior can be tested without shelling out to jq or
+// depending on gojq.
+type fakeProcessor struct {
+	out string
+	err error
+}
+
+func (f *fakeProcessor) Process(source io.Reader, program string) (io.Reader, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return bytes.NewReader([]byte(f.out)), nil
+}
+
+func (f *fakeProcessor) ProcessContext(ctx context.Context, source io.Reader, program string) (io.Reader, error) {
+	return f.Process(source, program)
+}
+
+func (f *fakeProcessor) ToggleCompact() {}
+func (f *fakeProcessor) ToggleRaw()     {}
+
+func TestWriteResultCreatesMissingDirectories(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a", "b", "out.json")
+
+	proc := &fakeProcessor{out: `{"ok":true}`}
+	if err := writeResult(proc, []byte(`{}`), ".", path, false); err != nil {
+		t.Fatalf("writeResult returned error: %v", err)
+	}
+
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("could not read written file: %v", err)
+	}
+	if string(got) != `{"ok":true}` {
+		t.Errorf("file contents = %q, want %q", got, `{"ok":true}`)
+	}
+
+	if _, err := os.Stat(path + ".jq"); !os.IsNotExist(err) {
+		t.Errorf("sidecar file was written without being requested")
+	}
+}
+
+func TestWriteResultWritesSidecarWhenRequested(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.json")
+
+	proc := &fakeProcessor{out: `{}`}
+	if err := writeResult(proc, []byte(`{}`), ".foo", path, true); err != nil {
+		t.Fatalf("writeResult returned error: %v", err)
+	}
+
+	got, err := ioutil.ReadFile(path + ".jq")
+	if err != nil {
+		t.Fatalf("could not read sidecar file: %v", err)
+	}
+	if string(got) != ".foo" {
+		t.Errorf("sidecar contents = %q, want %q", got, ".foo")
+	}
+}
+
+func TestWriteResultPropagatesProcessorError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.json")
+
+	wantErr := errors.New("boom")
+	proc := &fakeProcessor{err: wantErr}
+	if err := writeResult(proc, []byte(`{}`), ".", path, false); !errors.Is(err, wantErr) {
+		t.Fatalf("writeResult error = %v, want %v", err, wantErr)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("output file was written despite a processor error")
+	}
+}
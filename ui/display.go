@@ -0,0 +1,223 @@
+// Package ui handles user input, JSON processing output, and sending
+// interactions and commands to the JSON processor.
+//
+// The basic layout of the UI should include:
+// - One-row input for entering jq programs
+// - The rest of the container for displaying JSON processor output
+//
+// The data flow should flow through the following loop:
+// - Initial state
+// - Actions representing changes or events in the program
+// - Functions that listen for a given action and call methods on a UI
+//	 implementation to update the state
+// - Render the new state into application UI
+//
+// This should seem familiar to web programmers familiar with the Flux/Redux
+// flow. However, since Go doesn't have union types that carry data, and since
+// we want tighter control over memory, we use internal fields and buffers to
+// manage interim states and reuse memory.
+//
+// The UI surface itself lives behind the Display interface so the rendering
+// backend (termbox, tcell, ...) can be swapped without touching main's event
+// loop.
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// reservedRows is the number of rows the results viewport gives up to other
+// fixed UI chrome: the input line (row 0) and the status row (the last
+// row).
+const reservedRows = 2
+
+// Display is the surface main's event loop drives: it accepts input and
+// draws the input line, jq results, and status row for a given backend.
+type Display interface {
+	// Start initializes the backend with initialProgram in the input buffer.
+	Start(initialProgram string) error
+
+	// Events returns a channel of Actions the backend emits as the user
+	// interacts with it.
+	Events() chan (Action)
+
+	// Program returns the jq program currently in the input buffer.
+	Program() string
+	// UpdateInput inserts the pending input rune at the cursor.
+	UpdateInput()
+	// UpdateInputBackspace removes the character before the cursor.
+	UpdateInputBackspace()
+
+	// CursorLeft/Right move the input cursor by one rune.
+	CursorLeft()
+	CursorRight()
+	// WordLeft/Right move the input cursor by one word.
+	WordLeft()
+	WordRight()
+	// Home/End move the input cursor to the start/end of the program buffer.
+	Home()
+	End()
+	// DeleteForward removes the character under the cursor.
+	DeleteForward()
+	// KillToEnd removes from the cursor to the end of the program buffer,
+	// stashing it for a following Yank.
+	KillToEnd()
+	// Yank re-inserts the most recently killed text at the cursor.
+	Yank()
+	// HistoryPrev/Next walk backward/forward through submitted programs.
+	HistoryPrev()
+	HistoryNext()
+
+	// SaveMode reports whether the UI is showing the save-path prompt
+	// rather than the jq program input.
+	SaveMode() bool
+	// SetSaveMode switches the UI between the jq program input and the
+	// save-path prompt.
+	SetSaveMode(bool)
+	// SavePath returns the path currently entered in the save prompt.
+	SavePath() string
+	// UpdateSaveInput appends the pending input rune to the save path.
+	UpdateSaveInput()
+	// UpdateSaveInputBackspace removes the last character from the save
+	// path.
+	UpdateSaveInputBackspace()
+	// SetConfirmMode switches the save prompt between entering a path and
+	// answering an overwrite-confirmation question, so Esc/y/n key handling
+	// routes correctly.
+	SetConfirmMode(bool)
+
+	// RenderInput draws the jq program input line.
+	RenderInput() error
+	// RenderFilePrompt draws the save-path prompt.
+	RenderFilePrompt() error
+	// RenderConfirm draws an arbitrary yes/no confirmation prompt on the
+	// input row, e.g. to confirm overwriting an existing file.
+	RenderConfirm(prompt string) error
+	// RenderResults draws the jq output, replacing any previously rendered
+	// results.
+	RenderResults(data io.Reader) error
+
+	// SetStatus records the processor mode and last error shown by the next
+	// RenderStatus call.
+	SetStatus(Status)
+	// RenderStatus draws the persistent status/footer row: active mode
+	// indicators (COMPACT, RAW, SAVE), the last parse error, and the result
+	// line count.
+	RenderStatus() error
+
+	// ScrollDown/Up move the viewport by one line of output.
+	ScrollDown() error
+	ScrollUp() error
+	// ScrollPageDown/Up move the viewport by one screen height.
+	ScrollPageDown() error
+	ScrollPageUp() error
+	// ScrollTop/Bottom jump to the first or last line of output.
+	ScrollTop() error
+	ScrollBottom() error
+	// ScrollLeft/Right shift the viewport horizontally.
+	ScrollLeft() error
+	ScrollRight() error
+
+	// Size reports the backend's current width and height in cells.
+	Size() (int, int)
+
+	// Flush commits any pending draws to the terminal.
+	Flush() error
+
+	// Quit tears down the backend and returns the terminal to its prior
+	// state.
+	Quit()
+}
+
+// Action defines the events and interactions possible in the application
+type Action uint8
+
+// The following are the known Actions from the app to handle
+const (
+	ActionExit Action = iota
+	ActionInput
+	ActionInputBackspace
+	ActionPrint
+	ActionSaveInput
+	ActionSavePrompt
+	ActionSavePromptBackspace
+	ActionSaveSubmit
+	ActionSubmit
+	ActionToggleCompact
+	ActionToggleRaw
+	ActionScrollDown
+	ActionScrollUp
+	ActionScrollPageDown
+	ActionScrollPageUp
+	ActionScrollTop
+	ActionScrollBottom
+	ActionScrollLeft
+	ActionScrollRight
+	ActionCursorLeft
+	ActionCursorRight
+	ActionWordLeft
+	ActionWordRight
+	ActionHome
+	ActionEnd
+	ActionDeleteForward
+	ActionKillToEnd
+	ActionYank
+	ActionHistoryPrev
+	ActionHistoryNext
+	ActionSaveCancel
+	ActionSaveConfirmYes
+	ActionSaveConfirmNo
+	ActionToggleSaveSidecar
+)
+
+// altActions maps Alt-modified keys (chosen so they don't collide with
+// typing a jq program) to the Action they trigger: vim-style scrolling
+// (j/k/g/G/h/l) and emacs-style word jumps (b/f). Both backends share this
+// mapping so the keybindings stay identical.
+var altActions = map[rune]Action{
+	'j': ActionScrollDown,
+	'k': ActionScrollUp,
+	'g': ActionScrollTop,
+	'G': ActionScrollBottom,
+	'h': ActionScrollLeft,
+	'l': ActionScrollRight,
+	'b': ActionWordLeft,
+	'f': ActionWordRight,
+}
+
+// Status carries the processor mode and last error RenderStatus draws in
+// the footer row. SAVE mode and the result line count aren't included here
+// since each backend already tracks them itself.
+type Status struct {
+	Compact     bool
+	Raw         bool
+	LastError   string
+	SaveSidecar bool
+}
+
+// statusLine formats the footer row text shared by every backend.
+func statusLine(s Status, saveMode bool, lineCount int) string {
+	var modes []string
+	if s.Compact {
+		modes = append(modes, "COMPACT")
+	}
+	if s.Raw {
+		modes = append(modes, "RAW")
+	}
+	if saveMode {
+		mode := "SAVE"
+		if s.SaveSidecar {
+			mode += "+JQ"
+		}
+		modes = append(modes, mode)
+	}
+
+	line := strings.Join(modes, " ")
+	line += fmt.Sprintf("  lines: %d", lineCount)
+	if s.LastError != "" {
+		line += "  error: " + s.LastError
+	}
+	return line
+}
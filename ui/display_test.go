@@ -0,0 +1,61 @@
+package ui
+
+import "testing"
+
+func TestStatusLine(t *testing.T) {
+	cases := []struct {
+		name      string
+		status    Status
+		saveMode  bool
+		lineCount int
+		want      string
+	}{
+		{
+			name:      "no modes active",
+			status:    Status{},
+			lineCount: 5,
+			want:      "  lines: 5",
+		},
+		{
+			name:      "compact and raw",
+			status:    Status{Compact: true, Raw: true},
+			lineCount: 10,
+			want:      "COMPACT RAW  lines: 10",
+		},
+		{
+			name:      "save mode without sidecar",
+			status:    Status{},
+			saveMode:  true,
+			lineCount: 1,
+			want:      "SAVE  lines: 1",
+		},
+		{
+			name:      "save mode with sidecar",
+			status:    Status{SaveSidecar: true},
+			saveMode:  true,
+			lineCount: 1,
+			want:      "SAVE+JQ  lines: 1",
+		},
+		{
+			name:      "last error appended",
+			status:    Status{LastError: "boom"},
+			lineCount: 0,
+			want:      "  lines: 0  error: boom",
+		},
+		{
+			name:      "everything at once",
+			status:    Status{Compact: true, Raw: true, SaveSidecar: true, LastError: "boom"},
+			saveMode:  true,
+			lineCount: 3,
+			want:      "COMPACT RAW SAVE+JQ  lines: 3  error: boom",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := statusLine(tc.status, tc.saveMode, tc.lineCount); got != tc.want {
+				t.Errorf("statusLine() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
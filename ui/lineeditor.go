@@ -0,0 +1,248 @@
+package ui
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// historyFileName is created in the user's home directory to persist
+// submitted jq programs across sessions.
+const historyFileName = ".jq-live_history"
+
+// lineEditor is a readline-style editable text buffer for the jq program
+// input line. It is shared by every Display backend so cursor movement,
+// word-jumping, killing, yanking, and history behave identically regardless
+// of which terminal library is drawing it.
+type lineEditor struct {
+	buf    []rune
+	cursor int
+
+	killBuf []rune
+
+	history      []string
+	historyPos   int
+	historyStash []rune
+}
+
+// newLineEditor returns a lineEditor seeded with initial text and loaded
+// from the on-disk history file, if any.
+func newLineEditor(initial string) *lineEditor {
+	le := &lineEditor{buf: []rune(initial), history: loadHistory()}
+	le.cursor = len(le.buf)
+	le.historyPos = len(le.history)
+	return le
+}
+
+// String returns the buffer's current contents.
+func (le *lineEditor) String() string {
+	return string(le.buf)
+}
+
+// Cursor returns the buffer's logical cursor position, in runes.
+func (le *lineEditor) Cursor() int {
+	return le.cursor
+}
+
+// Insert adds r at the cursor and advances past it.
+func (le *lineEditor) Insert(r rune) {
+	le.buf = append(le.buf[:le.cursor:le.cursor], append([]rune{r}, le.buf[le.cursor:]...)...)
+	le.cursor++
+}
+
+// DeleteBackward removes the character before the cursor, if any.
+func (le *lineEditor) DeleteBackward() {
+	if le.cursor == 0 {
+		return
+	}
+	le.buf = append(le.buf[:le.cursor-1], le.buf[le.cursor:]...)
+	le.cursor--
+}
+
+// DeleteForward removes the character under the cursor, if any.
+func (le *lineEditor) DeleteForward() {
+	if le.cursor >= len(le.buf) {
+		return
+	}
+	le.buf = append(le.buf[:le.cursor], le.buf[le.cursor+1:]...)
+}
+
+// MoveLeft moves the cursor back one rune.
+func (le *lineEditor) MoveLeft() {
+	if le.cursor > 0 {
+		le.cursor--
+	}
+}
+
+// MoveRight moves the cursor forward one rune.
+func (le *lineEditor) MoveRight() {
+	if le.cursor < len(le.buf) {
+		le.cursor++
+	}
+}
+
+// Home moves the cursor to the start of the buffer.
+func (le *lineEditor) Home() {
+	le.cursor = 0
+}
+
+// End moves the cursor to the end of the buffer.
+func (le *lineEditor) End() {
+	le.cursor = len(le.buf)
+}
+
+// WordLeft moves the cursor to the start of the current or previous word.
+func (le *lineEditor) WordLeft() {
+	le.cursor = prevWordBoundary(le.buf, le.cursor)
+}
+
+// WordRight moves the cursor to the end of the current or next word.
+func (le *lineEditor) WordRight() {
+	le.cursor = nextWordBoundary(le.buf, le.cursor)
+}
+
+// KillToEnd removes everything from the cursor to the end of the buffer,
+// stashing it so a following Yank can restore it.
+func (le *lineEditor) KillToEnd() {
+	le.killBuf = append([]rune{}, le.buf[le.cursor:]...)
+	le.buf = le.buf[:le.cursor]
+}
+
+// Yank re-inserts the most recently killed text at the cursor.
+func (le *lineEditor) Yank() {
+	if len(le.killBuf) == 0 {
+		return
+	}
+	rest := append([]rune{}, le.buf[le.cursor:]...)
+	le.buf = append(le.buf[:le.cursor:le.cursor], append(append([]rune{}, le.killBuf...), rest...)...)
+	le.cursor += len(le.killBuf)
+}
+
+// Set replaces the buffer wholesale and places the cursor at its end. Used
+// to restore a history entry.
+func (le *lineEditor) Set(s string) {
+	le.buf = []rune(s)
+	le.cursor = len(le.buf)
+}
+
+// HistoryPrev walks backward to the previous history entry, stashing the
+// in-progress buffer on the first call so HistoryNext can return to it.
+func (le *lineEditor) HistoryPrev() {
+	if len(le.history) == 0 || le.historyPos == 0 {
+		return
+	}
+	if le.historyPos == len(le.history) {
+		le.historyStash = append([]rune{}, le.buf...)
+	}
+	le.historyPos--
+	le.Set(le.history[le.historyPos])
+}
+
+// HistoryNext walks forward through history, returning to the stashed
+// in-progress buffer once the newest entry is passed.
+func (le *lineEditor) HistoryNext() {
+	if le.historyPos >= len(le.history) {
+		return
+	}
+	le.historyPos++
+	if le.historyPos == len(le.history) {
+		le.Set(string(le.historyStash))
+		return
+	}
+	le.Set(le.history[le.historyPos])
+}
+
+// Submit records the current buffer as a new history entry, both in memory
+// and appended to the on-disk history file, and resets history browsing.
+func (le *lineEditor) Submit() {
+	program := le.String()
+	if program == "" {
+		return
+	}
+	if n := len(le.history); n == 0 || le.history[n-1] != program {
+		le.history = append(le.history, program)
+		appendHistory(program)
+	}
+	le.historyPos = len(le.history)
+}
+
+// isWordRune reports whether r is part of a "word" for word-jump purposes;
+// anything but whitespace counts, matching most readline implementations.
+func isWordRune(r rune) bool {
+	return !unicode.IsSpace(r)
+}
+
+func prevWordBoundary(buf []rune, cursor int) int {
+	i := cursor
+	for i > 0 && !isWordRune(buf[i-1]) {
+		i--
+	}
+	for i > 0 && isWordRune(buf[i-1]) {
+		i--
+	}
+	return i
+}
+
+func nextWordBoundary(buf []rune, cursor int) int {
+	i := cursor
+	for i < len(buf) && !isWordRune(buf[i]) {
+		i++
+	}
+	for i < len(buf) && isWordRune(buf[i]) {
+		i++
+	}
+	return i
+}
+
+// historyFilePath returns ~/.jq-live_history, or an error if the home
+// directory can't be determined.
+func historyFilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, historyFileName), nil
+}
+
+// loadHistory reads previously submitted programs from the history file.
+// Any failure to locate or read it is treated as "no history" rather than
+// an error, since history is a convenience, not a requirement to run.
+func loadHistory() []string {
+	path, err := historyFilePath()
+	if err != nil {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var history []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			history = append(history, line)
+		}
+	}
+	return history
+}
+
+// appendHistory records program as the newest history entry. Failures are
+// silently ignored for the same reason as loadHistory.
+func appendHistory(program string) {
+	path, err := historyFilePath()
+	if err != nil {
+		return
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	fmt.Fprintln(f, program)
+}
@@ -0,0 +1,234 @@
+package ui
+
+import "testing"
+
+// newTestLineEditor returns a lineEditor seeded with initial, isolated from
+// the real ~/.jq-live_history so tests don't read or write the caller's
+// actual history file.
+func newTestLineEditor(t *testing.T, initial string) *lineEditor {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	return newLineEditor(initial)
+}
+
+func TestLineEditorInsert(t *testing.T) {
+	cases := []struct {
+		name       string
+		initial    string
+		cursor     int
+		insert     rune
+		wantBuf    string
+		wantCursor int
+	}{
+		{"into empty", "", 0, 'a', "a", 1},
+		{"at start", "bc", 0, 'a', "abc", 1},
+		{"at end", "ab", 2, 'c', "abc", 3},
+		{"in middle", "ac", 1, 'b', "abc", 2},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			le := newTestLineEditor(t, tc.initial)
+			le.cursor = tc.cursor
+
+			le.Insert(tc.insert)
+
+			if got := le.String(); got != tc.wantBuf {
+				t.Errorf("String() = %q, want %q", got, tc.wantBuf)
+			}
+			if le.Cursor() != tc.wantCursor {
+				t.Errorf("Cursor() = %d, want %d", le.Cursor(), tc.wantCursor)
+			}
+		})
+	}
+}
+
+func TestLineEditorDeleteBackward(t *testing.T) {
+	cases := []struct {
+		name       string
+		initial    string
+		cursor     int
+		wantBuf    string
+		wantCursor int
+	}{
+		{"at start is a no-op", "abc", 0, "abc", 0},
+		{"at end", "abc", 3, "ab", 2},
+		{"in middle", "abc", 1, "bc", 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			le := newTestLineEditor(t, tc.initial)
+			le.cursor = tc.cursor
+
+			le.DeleteBackward()
+
+			if got := le.String(); got != tc.wantBuf {
+				t.Errorf("String() = %q, want %q", got, tc.wantBuf)
+			}
+			if le.Cursor() != tc.wantCursor {
+				t.Errorf("Cursor() = %d, want %d", le.Cursor(), tc.wantCursor)
+			}
+		})
+	}
+}
+
+func TestLineEditorDeleteForward(t *testing.T) {
+	cases := []struct {
+		name       string
+		initial    string
+		cursor     int
+		wantBuf    string
+		wantCursor int
+	}{
+		{"at end is a no-op", "abc", 3, "abc", 3},
+		{"at start", "abc", 0, "bc", 0},
+		{"in middle", "abc", 1, "ac", 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			le := newTestLineEditor(t, tc.initial)
+			le.cursor = tc.cursor
+
+			le.DeleteForward()
+
+			if got := le.String(); got != tc.wantBuf {
+				t.Errorf("String() = %q, want %q", got, tc.wantBuf)
+			}
+			if le.Cursor() != tc.wantCursor {
+				t.Errorf("Cursor() = %d, want %d", le.Cursor(), tc.wantCursor)
+			}
+		})
+	}
+}
+
+func TestLineEditorWordBoundaries(t *testing.T) {
+	cases := []struct {
+		name     string
+		buf      string
+		cursor   int
+		wantPrev int
+		wantNext int
+	}{
+		{"middle of a word", "foo bar baz", 5, 4, 7},
+		{"at start of a word", "foo bar baz", 4, 0, 7},
+		{"in leading whitespace", "  foo", 1, 0, 5},
+		{"at end of buffer", "foo bar", 7, 4, 7},
+		{"at start of buffer", "foo bar", 0, 0, 3},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := []rune(tc.buf)
+			if got := prevWordBoundary(buf, tc.cursor); got != tc.wantPrev {
+				t.Errorf("prevWordBoundary(%q, %d) = %d, want %d", tc.buf, tc.cursor, got, tc.wantPrev)
+			}
+			if got := nextWordBoundary(buf, tc.cursor); got != tc.wantNext {
+				t.Errorf("nextWordBoundary(%q, %d) = %d, want %d", tc.buf, tc.cursor, got, tc.wantNext)
+			}
+		})
+	}
+}
+
+func TestLineEditorKillAndYank(t *testing.T) {
+	le := newTestLineEditor(t, "foo bar")
+	le.cursor = 3
+
+	le.KillToEnd()
+	if got, want := le.String(), "foo"; got != want {
+		t.Fatalf("after KillToEnd: String() = %q, want %q", got, want)
+	}
+	if le.Cursor() != 3 {
+		t.Fatalf("after KillToEnd: Cursor() = %d, want 3", le.Cursor())
+	}
+
+	le.Insert('!')
+	if got, want := le.String(), "foo!"; got != want {
+		t.Fatalf("after Insert: String() = %q, want %q", got, want)
+	}
+
+	le.Yank()
+	if got, want := le.String(), "foo! bar"; got != want {
+		t.Fatalf("after Yank: String() = %q, want %q", got, want)
+	}
+	if got, want := le.Cursor(), len([]rune("foo!"))+len([]rune(" bar")); got != want {
+		t.Fatalf("after Yank: Cursor() = %d, want %d", got, want)
+	}
+}
+
+func TestLineEditorYankWithNothingKilledIsNoop(t *testing.T) {
+	le := newTestLineEditor(t, "foo")
+	le.cursor = 1
+
+	le.Yank()
+
+	if got, want := le.String(), "foo"; got != want {
+		t.Fatalf("String() = %q, want %q", got, want)
+	}
+	if le.Cursor() != 1 {
+		t.Fatalf("Cursor() = %d, want 1", le.Cursor())
+	}
+}
+
+func TestLineEditorHistoryRoundTrip(t *testing.T) {
+	le := newTestLineEditor(t, "")
+
+	le.Set(".foo")
+	le.Submit()
+	le.Set(".bar")
+	le.Submit()
+
+	// In-progress buffer, not yet submitted.
+	le.Set(".in-progress")
+
+	le.HistoryPrev()
+	if got, want := le.String(), ".bar"; got != want {
+		t.Fatalf("after first HistoryPrev: String() = %q, want %q", got, want)
+	}
+
+	le.HistoryPrev()
+	if got, want := le.String(), ".foo"; got != want {
+		t.Fatalf("after second HistoryPrev: String() = %q, want %q", got, want)
+	}
+
+	// No more history; further HistoryPrev is a no-op.
+	le.HistoryPrev()
+	if got, want := le.String(), ".foo"; got != want {
+		t.Fatalf("HistoryPrev at oldest entry: String() = %q, want %q", got, want)
+	}
+
+	le.HistoryNext()
+	if got, want := le.String(), ".bar"; got != want {
+		t.Fatalf("after first HistoryNext: String() = %q, want %q", got, want)
+	}
+
+	le.HistoryNext()
+	if got, want := le.String(), ".in-progress"; got != want {
+		t.Fatalf("after walking past newest entry: String() = %q, want %q", got, want)
+	}
+
+	// Back at the stashed buffer; further HistoryNext is a no-op.
+	le.HistoryNext()
+	if got, want := le.String(), ".in-progress"; got != want {
+		t.Fatalf("HistoryNext past newest: String() = %q, want %q", got, want)
+	}
+}
+
+func TestLineEditorSubmitSkipsEmptyAndDuplicates(t *testing.T) {
+	le := newTestLineEditor(t, "")
+
+	le.Set("")
+	le.Submit()
+	if len(le.history) != 0 {
+		t.Fatalf("Submit of empty buffer recorded history: %v", le.history)
+	}
+
+	le.Set(".foo")
+	le.Submit()
+	le.Set(".foo")
+	le.Submit()
+	if got, want := len(le.history), 1; got != want {
+		t.Fatalf("Submit of a repeated program recorded %d entries, want %d: %v", got, want, le.history)
+	}
+}
@@ -0,0 +1,241 @@
+package ui
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// tokenColor is a backend-neutral syntax highlight color for one span of a
+// result line. Each Display backend maps these to its own color type when
+// drawing.
+type tokenColor int
+
+// The highlight colors a ResultView can tag a span with.
+const (
+	tokenColorDefault tokenColor = iota
+	tokenColorKey
+	tokenColorString
+	tokenColorNumber
+	tokenColorBool
+	tokenColorNull
+	tokenColorPunct
+)
+
+// resultSpan is a contiguous run of a result line's text sharing one
+// highlight color.
+type resultSpan struct {
+	text  string
+	color tokenColor
+}
+
+// resultLine is one line of jq output, pre-split into highlighted spans so
+// scrolling never has to re-tokenize it.
+type resultLine struct {
+	spans []resultSpan
+}
+
+// ResultView holds the last processed jq output as a line-indexed,
+// pre-highlighted buffer along with the viewer's current scroll position.
+// It lets a Display redraw the visible viewport on navigation keys without
+// re-reading or re-highlighting the underlying output.
+type ResultView struct {
+	lines   []resultLine
+	scrollY int
+	scrollX int
+}
+
+// newResultView tokenizes data into color-tagged lines. It reads all of data
+// up front with bufio.Reader.ReadBytes and highlights every line before
+// returning; lines are not read or tokenized lazily as the viewport reaches
+// them.
+func newResultView(data io.Reader) (*ResultView, error) {
+	var lines []resultLine
+
+	rows := bufio.NewReader(data)
+	for {
+		row, err := rows.ReadBytes('\n')
+		if len(row) > 0 {
+			lines = append(lines, highlightLine(bytes.TrimRight(row, "\n")))
+		}
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+	}
+
+	return &ResultView{lines: lines}, nil
+}
+
+// horizontalScrollStep is the number of columns Scroll{Left,Right} move per
+// keypress.
+const horizontalScrollStep = 4
+
+// scrollBy moves the viewport vertically by delta lines, clamped to the
+// start and end of the buffer given the current viewport height.
+func (rv *ResultView) scrollBy(delta, viewportH int) {
+	y := rv.scrollY + delta
+	if max := rv.maxScrollY(viewportH); y > max {
+		y = max
+	}
+	if y < 0 {
+		y = 0
+	}
+	rv.scrollY = y
+}
+
+// scrollByX moves the viewport horizontally by delta columns.
+func (rv *ResultView) scrollByX(delta int) {
+	x := rv.scrollX + delta
+	if x < 0 {
+		x = 0
+	}
+	rv.scrollX = x
+}
+
+// scrollToTop jumps the viewport to the first line of output.
+func (rv *ResultView) scrollToTop() {
+	rv.scrollY = 0
+}
+
+// scrollToBottom jumps the viewport to the last full screen of output.
+func (rv *ResultView) scrollToBottom(viewportH int) {
+	rv.scrollY = rv.maxScrollY(viewportH)
+}
+
+func (rv *ResultView) maxScrollY(viewportH int) int {
+	max := len(rv.lines) - viewportH
+	if max < 0 {
+		max = 0
+	}
+	return max
+}
+
+// tokenKind classifies a run of characters produced by tokenizeLine.
+type tokenKind int
+
+const (
+	tokString tokenKind = iota
+	tokNumber
+	tokBool
+	tokNull
+	tokPunct
+	tokOther
+)
+
+type rawToken struct {
+	text string
+	kind tokenKind
+}
+
+// tokenizeLine splits one line of jq output into runs of JSON tokens:
+// strings, numbers, booleans, null, structural punctuation, and whitespace
+// or other filler.
+func tokenizeLine(line []byte) []rawToken {
+	s := string(line)
+
+	var toks []rawToken
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == '"':
+			j := i + 1
+			for j < len(s) {
+				if s[j] == '\\' && j+1 < len(s) {
+					j += 2
+					continue
+				}
+				if s[j] == '"' {
+					j++
+					break
+				}
+				j++
+			}
+			toks = append(toks, rawToken{s[i:j], tokString})
+			i = j
+
+		case strings.ContainsRune("{}[],:", rune(c)):
+			toks = append(toks, rawToken{s[i : i+1], tokPunct})
+			i++
+
+		case strings.HasPrefix(s[i:], "true"):
+			toks = append(toks, rawToken{"true", tokBool})
+			i += 4
+
+		case strings.HasPrefix(s[i:], "false"):
+			toks = append(toks, rawToken{"false", tokBool})
+			i += 5
+
+		case strings.HasPrefix(s[i:], "null"):
+			toks = append(toks, rawToken{"null", tokNull})
+			i += 4
+
+		case c == '-' || (c >= '0' && c <= '9'):
+			j := i
+			for j < len(s) && strings.ContainsRune("-+.eE0123456789", rune(s[j])) {
+				j++
+			}
+			toks = append(toks, rawToken{s[i:j], tokNumber})
+			i = j
+
+		default:
+			j := i + 1
+			for j < len(s) && !strings.ContainsRune(`"{}[],:`, rune(s[j])) &&
+				!strings.HasPrefix(s[j:], "true") && !strings.HasPrefix(s[j:], "false") &&
+				!strings.HasPrefix(s[j:], "null") && !(s[j] == '-' || (s[j] >= '0' && s[j] <= '9')) {
+				j++
+			}
+			toks = append(toks, rawToken{s[i:j], tokOther})
+			i = j
+		}
+	}
+
+	return toks
+}
+
+// highlightLine tokenizes a line and assigns each token a highlight color,
+// distinguishing object keys (a string token followed by ":") from string
+// values.
+func highlightLine(line []byte) resultLine {
+	toks := tokenizeLine(line)
+
+	spans := make([]resultSpan, 0, len(toks))
+	for idx, tok := range toks {
+		var color tokenColor
+		switch tok.kind {
+		case tokString:
+			if tokenIsKey(toks, idx) {
+				color = tokenColorKey
+			} else {
+				color = tokenColorString
+			}
+		case tokNumber:
+			color = tokenColorNumber
+		case tokBool:
+			color = tokenColorBool
+		case tokNull:
+			color = tokenColorNull
+		case tokPunct:
+			color = tokenColorPunct
+		default:
+			color = tokenColorDefault
+		}
+		spans = append(spans, resultSpan{text: tok.text, color: color})
+	}
+
+	return resultLine{spans: spans}
+}
+
+// tokenIsKey reports whether the string token at idx is followed, ignoring
+// whitespace, by a ':' punctuation token.
+func tokenIsKey(toks []rawToken, idx int) bool {
+	for j := idx + 1; j < len(toks); j++ {
+		if toks[j].kind == tokOther && strings.TrimSpace(toks[j].text) == "" {
+			continue
+		}
+		return toks[j].kind == tokPunct && toks[j].text == ":"
+	}
+	return false
+}
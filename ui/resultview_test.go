@@ -0,0 +1,162 @@
+package ui
+
+import "testing"
+
+func TestResultViewScrollByClampsToRange(t *testing.T) {
+	cases := []struct {
+		name      string
+		lines     int
+		viewportH int
+		startY    int
+		delta     int
+		wantY     int
+	}{
+		{"scrolls down within range", 10, 4, 0, 2, 2},
+		{"clamps at max when scrolling past the end", 10, 4, 0, 100, 6},
+		{"clamps at zero when scrolling past the start", 10, 4, 2, -100, 0},
+		{"viewport taller than content clamps max to zero", 3, 10, 0, 5, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rv := &ResultView{lines: make([]resultLine, tc.lines), scrollY: tc.startY}
+			rv.scrollBy(tc.delta, tc.viewportH)
+			if rv.scrollY != tc.wantY {
+				t.Errorf("scrollY = %d, want %d", rv.scrollY, tc.wantY)
+			}
+		})
+	}
+}
+
+func TestResultViewMaxScrollY(t *testing.T) {
+	cases := []struct {
+		name      string
+		lines     int
+		viewportH int
+		want      int
+	}{
+		{"content longer than viewport", 10, 4, 6},
+		{"content shorter than viewport", 3, 10, 0},
+		{"content exactly fills viewport", 4, 4, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rv := &ResultView{lines: make([]resultLine, tc.lines)}
+			if got := rv.maxScrollY(tc.viewportH); got != tc.want {
+				t.Errorf("maxScrollY(%d) = %d, want %d", tc.viewportH, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResultViewScrollToBottom(t *testing.T) {
+	rv := &ResultView{lines: make([]resultLine, 10)}
+	rv.scrollToBottom(4)
+	if rv.scrollY != 6 {
+		t.Errorf("scrollY = %d, want 6", rv.scrollY)
+	}
+}
+
+func TestResultViewScrollToTop(t *testing.T) {
+	rv := &ResultView{lines: make([]resultLine, 10), scrollY: 6}
+	rv.scrollToTop()
+	if rv.scrollY != 0 {
+		t.Errorf("scrollY = %d, want 0", rv.scrollY)
+	}
+}
+
+func TestResultViewScrollByXClampsAtZero(t *testing.T) {
+	rv := &ResultView{}
+
+	rv.scrollByX(horizontalScrollStep)
+	if rv.scrollX != horizontalScrollStep {
+		t.Fatalf("scrollX = %d, want %d", rv.scrollX, horizontalScrollStep)
+	}
+
+	rv.scrollByX(-100)
+	if rv.scrollX != 0 {
+		t.Fatalf("scrollX = %d, want 0", rv.scrollX)
+	}
+}
+
+func TestTokenizeLine(t *testing.T) {
+	cases := []struct {
+		name string
+		line string
+		want []rawToken
+	}{
+		{
+			name: "string with an escaped quote",
+			line: `"a\"b"`,
+			want: []rawToken{{`"a\"b"`, tokString}},
+		},
+		{
+			name: "object with key and string value",
+			line: `{"key": "value"}`,
+			want: []rawToken{
+				{"{", tokPunct},
+				{`"key"`, tokString},
+				{":", tokPunct},
+				{" ", tokOther},
+				{`"value"`, tokString},
+				{"}", tokPunct},
+			},
+		},
+		{
+			name: "literals",
+			line: `[true, false, null]`,
+			want: []rawToken{
+				{"[", tokPunct},
+				{"true", tokBool},
+				{",", tokPunct},
+				{" ", tokOther},
+				{"false", tokBool},
+				{",", tokPunct},
+				{" ", tokOther},
+				{"null", tokNull},
+				{"]", tokPunct},
+			},
+		},
+		{
+			name: "negative and fractional numbers",
+			line: `-1.5`,
+			want: []rawToken{{"-1.5", tokNumber}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tokenizeLine([]byte(tc.line))
+			if len(got) != len(tc.want) {
+				t.Fatalf("tokenizeLine(%q) = %+v, want %+v", tc.line, got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("token %d: got %+v, want %+v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHighlightLineDistinguishesKeysFromValues(t *testing.T) {
+	line := highlightLine([]byte(`{"key": "value"}`))
+
+	var gotKey, gotValue bool
+	for _, sp := range line.spans {
+		switch sp.text {
+		case `"key"`:
+			gotKey = sp.color == tokenColorKey
+		case `"value"`:
+			gotValue = sp.color == tokenColorString
+		}
+	}
+
+	if !gotKey {
+		t.Error(`"key" span was not colored tokenColorKey`)
+	}
+	if !gotValue {
+		t.Error(`"value" span was not colored tokenColorString`)
+	}
+}
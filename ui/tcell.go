@@ -0,0 +1,406 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// Tcell draws the jq-live UI via gdamore/tcell. It implements Display and
+// exists alongside Termbox to work around termbox-go's occasional
+// PollEvent panics (see the recover in Termbox.Events), while adding
+// true-color output, wide-rune-aware column widths (runewidth), and resize
+// events.
+type Tcell struct {
+	Debug       io.WriteCloser
+	input       *lineEditor
+	saveMode    bool
+	savePath    string
+	confirmMode bool
+	newInput    rune
+	events      chan (Action)
+	flushLock   sync.Mutex
+	resultView  *ResultView
+	status      Status
+
+	screen tcell.Screen
+}
+
+// Start initializes the terminal screen with initialProgram in the input
+// buffer.
+func (t *Tcell) Start(initialProgram string) error {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return fmt.Errorf("could not create tcell screen: %v", err)
+	}
+	if err := screen.Init(); err != nil {
+		return fmt.Errorf("could not init tcell screen: %v", err)
+	}
+	screen.SetStyle(tcell.StyleDefault)
+	screen.Clear()
+	screen.ShowCursor(0, 0)
+
+	t.screen = screen
+	t.input = newLineEditor(initialProgram)
+
+	return nil
+}
+
+// Program returns the jq program currently in the input buffer.
+func (t *Tcell) Program() string {
+	return t.input.String()
+}
+
+// SaveMode reports whether the UI is currently showing the save-path prompt
+// rather than the jq program input.
+func (t *Tcell) SaveMode() bool {
+	return t.saveMode
+}
+
+// SetSaveMode switches the UI between the jq program input and the
+// save-path prompt.
+func (t *Tcell) SetSaveMode(v bool) {
+	t.saveMode = v
+}
+
+// SavePath returns the path currently entered in the save prompt.
+func (t *Tcell) SavePath() string {
+	return t.savePath
+}
+
+// SetConfirmMode switches the save prompt between entering a path and
+// answering an overwrite-confirmation question.
+func (t *Tcell) SetConfirmMode(v bool) {
+	t.confirmMode = v
+}
+
+// UpdateInput inserts the pending input rune at the cursor.
+func (t *Tcell) UpdateInput() {
+	if t.newInput != 0 {
+		t.input.Insert(t.newInput)
+		t.newInput = 0
+	}
+}
+
+// UpdateInputBackspace removes the character before the cursor.
+func (t *Tcell) UpdateInputBackspace() {
+	t.input.DeleteBackward()
+}
+
+// CursorLeft moves the input cursor back one rune.
+func (t *Tcell) CursorLeft() { t.input.MoveLeft() }
+
+// CursorRight moves the input cursor forward one rune.
+func (t *Tcell) CursorRight() { t.input.MoveRight() }
+
+// WordLeft moves the input cursor to the start of the current or previous
+// word.
+func (t *Tcell) WordLeft() { t.input.WordLeft() }
+
+// WordRight moves the input cursor to the end of the current or next word.
+func (t *Tcell) WordRight() { t.input.WordRight() }
+
+// Home moves the input cursor to the start of the program buffer.
+func (t *Tcell) Home() { t.input.Home() }
+
+// End moves the input cursor to the end of the program buffer.
+func (t *Tcell) End() { t.input.End() }
+
+// DeleteForward removes the character under the cursor.
+func (t *Tcell) DeleteForward() { t.input.DeleteForward() }
+
+// KillToEnd removes from the cursor to the end of the program buffer.
+func (t *Tcell) KillToEnd() { t.input.KillToEnd() }
+
+// Yank re-inserts the most recently killed text at the cursor.
+func (t *Tcell) Yank() { t.input.Yank() }
+
+// HistoryPrev replaces the program buffer with the previous history entry.
+func (t *Tcell) HistoryPrev() { t.input.HistoryPrev() }
+
+// HistoryNext replaces the program buffer with the next history entry.
+func (t *Tcell) HistoryNext() { t.input.HistoryNext() }
+
+// UpdateSaveInput appends the pending input rune to the save path.
+func (t *Tcell) UpdateSaveInput() {
+	if t.newInput != 0 {
+		t.savePath += string(t.newInput)
+		t.newInput = 0
+	}
+}
+
+// UpdateSaveInputBackspace removes the last character from the save path.
+func (t *Tcell) UpdateSaveInputBackspace() {
+	if len(t.savePath) == 0 {
+		return
+	}
+	t.savePath = t.savePath[0 : len(t.savePath)-1]
+}
+
+// Events returns a channel of Actions that are sent through the application.
+// A resize is handled inline here rather than forwarded as an Action, since
+// redrawing the current state at the new size needs no input from main's
+// event loop. (Termbox does not handle resize events at all.)
+func (t *Tcell) Events() chan (Action) {
+	t.events = make(chan (Action))
+
+	go func() {
+		for {
+			switch ev := t.screen.PollEvent().(type) {
+			case *tcell.EventResize:
+				t.screen.Sync()
+				t.redraw()
+
+			case *tcell.EventKey:
+				switch key := ev.Key(); key {
+				case tcell.KeyCtrlC:
+					t.events <- ActionExit
+				case tcell.KeyEsc:
+					switch {
+					case t.confirmMode:
+						t.events <- ActionSaveConfirmNo
+					case t.saveMode:
+						t.events <- ActionSaveCancel
+					default:
+						t.events <- ActionExit
+					}
+				// Ctrl+T toggles compact mode; Ctrl+E is reserved below for
+				// emacs-style "end of line" now that the input line supports
+				// cursor movement.
+				case tcell.KeyCtrlT:
+					t.events <- ActionToggleCompact
+				case tcell.KeyCtrlP:
+					t.events <- ActionPrint
+				case tcell.KeyCtrlR:
+					t.events <- ActionToggleRaw
+				case tcell.KeyCtrlS:
+					t.events <- ActionSavePrompt
+				case tcell.KeyCtrlW:
+					if t.saveMode && !t.confirmMode {
+						t.events <- ActionToggleSaveSidecar
+					}
+				case tcell.KeyCtrlA, tcell.KeyHome:
+					if !t.saveMode {
+						t.events <- ActionHome
+					}
+				case tcell.KeyCtrlE, tcell.KeyEnd:
+					if !t.saveMode {
+						t.events <- ActionEnd
+					}
+				case tcell.KeyCtrlD:
+					if !t.saveMode {
+						t.events <- ActionDeleteForward
+					}
+				case tcell.KeyCtrlK:
+					if !t.saveMode {
+						t.events <- ActionKillToEnd
+					}
+				case tcell.KeyCtrlY:
+					if !t.saveMode {
+						t.events <- ActionYank
+					}
+				case tcell.KeyLeft:
+					if !t.saveMode {
+						if ev.Modifiers()&tcell.ModCtrl != 0 {
+							t.events <- ActionWordLeft
+						} else {
+							t.events <- ActionCursorLeft
+						}
+					}
+				case tcell.KeyRight:
+					if !t.saveMode {
+						if ev.Modifiers()&tcell.ModCtrl != 0 {
+							t.events <- ActionWordRight
+						} else {
+							t.events <- ActionCursorRight
+						}
+					}
+				case tcell.KeyUp:
+					if !t.saveMode {
+						t.events <- ActionHistoryPrev
+					}
+				case tcell.KeyDown:
+					if !t.saveMode {
+						t.events <- ActionHistoryNext
+					}
+				case tcell.KeyPgUp:
+					t.events <- ActionScrollPageUp
+				case tcell.KeyPgDn:
+					t.events <- ActionScrollPageDown
+				case tcell.KeyEnter:
+					switch {
+					case t.confirmMode:
+						t.events <- ActionSaveConfirmYes
+					case t.saveMode:
+						t.events <- ActionSaveSubmit
+					default:
+						t.input.Submit()
+						t.events <- ActionSubmit
+					}
+				case tcell.KeyBackspace, tcell.KeyBackspace2:
+					switch {
+					case t.confirmMode:
+					case t.saveMode:
+						t.events <- ActionSavePromptBackspace
+					default:
+						t.events <- ActionInputBackspace
+					}
+				case tcell.KeyRune:
+					r := ev.Rune()
+					if t.confirmMode {
+						switch r {
+						case 'y', 'Y':
+							t.events <- ActionSaveConfirmYes
+						case 'n', 'N':
+							t.events <- ActionSaveConfirmNo
+						}
+						continue
+					}
+
+					if action, ok := altActions[r]; ok && ev.Modifiers()&tcell.ModAlt != 0 {
+						t.events <- action
+						continue
+					}
+
+					t.newInput = r
+					if t.saveMode {
+						t.events <- ActionSaveInput
+					} else {
+						t.events <- ActionInput
+					}
+				}
+			}
+		}
+	}()
+
+	return t.events
+}
+
+// redraw repaints the input row and result viewport at the screen's current
+// size, e.g. after a resize.
+func (t *Tcell) redraw() {
+	if t.saveMode {
+		t.renderInputRow(filePrompt+t.savePath, len(filePrompt)+len([]rune(t.savePath)))
+	} else {
+		t.renderInputRow(t.input.String(), t.input.Cursor())
+	}
+	t.drawResultView()
+	t.RenderStatus()
+}
+
+// RenderInput updates the input display to match the internal buffer,
+// placing the terminal cursor at the input line's logical cursor position.
+func (t *Tcell) RenderInput() error {
+	return t.renderInputRow(t.input.String(), t.input.Cursor())
+}
+
+// RenderFilePrompt switches the UI to the file input
+func (t *Tcell) RenderFilePrompt() error {
+	if !t.saveMode {
+		return nil
+	}
+	prompt := filePrompt + t.savePath
+	return t.renderInputRow(prompt, len([]rune(prompt)))
+}
+
+func (t *Tcell) renderInputRow(text string, cursor int) error {
+	w, _ := t.screen.Size()
+
+	var x int
+	for _, r := range text {
+		t.screen.SetContent(x, 0, r, nil, tcell.StyleDefault)
+		x += runewidth.RuneWidth(r)
+	}
+	for ; x < w; x++ {
+		t.screen.SetContent(x, 0, ' ', nil, tcell.StyleDefault)
+	}
+
+	t.screen.ShowCursor(cursor, 0)
+	return t.Flush()
+}
+
+// RenderConfirm draws an arbitrary yes/no confirmation prompt on the input
+// row, e.g. to confirm overwriting an existing file.
+func (t *Tcell) RenderConfirm(prompt string) error {
+	return t.renderInputRow(prompt, len([]rune(prompt)))
+}
+
+// RenderResults tokenizes the jq output into a line-indexed, syntax
+// highlighted ResultView and draws the first viewport's worth of it. The
+// ResultView is cached on the Tcell so later scrolling redraws reuse the
+// same highlighting work instead of re-reading data.
+func (t *Tcell) RenderResults(data io.Reader) error {
+	rv, err := newResultView(data)
+	if err != nil {
+		return fmt.Errorf("could not process results: %v", err)
+	}
+
+	t.resultView = rv
+	return t.drawResultView()
+}
+
+// SetStatus records the processor mode and last error for the next
+// RenderStatus call.
+func (t *Tcell) SetStatus(s Status) {
+	t.status = s
+}
+
+// RenderStatus draws the persistent status/footer row: active mode
+// indicators (COMPACT, RAW, SAVE), the last parse error, and the result
+// line count.
+func (t *Tcell) RenderStatus() error {
+	var lineCount int
+	if t.resultView != nil {
+		lineCount = len(t.resultView.lines)
+	}
+	line := statusLine(t.status, t.saveMode, lineCount)
+
+	w, h := t.screen.Size()
+	y := h - 1
+
+	var x int
+	for _, r := range line {
+		if x >= w {
+			break
+		}
+		t.screen.SetContent(x, y, r, nil, tcell.StyleDefault)
+		x += runewidth.RuneWidth(r)
+	}
+	for ; x < w; x++ {
+		t.screen.SetContent(x, y, ' ', nil, tcell.StyleDefault)
+	}
+
+	return t.Flush()
+}
+
+// Size reports tcell's current terminal dimensions in cells.
+func (t *Tcell) Size() (int, int) {
+	return t.screen.Size()
+}
+
+// Flush commits any pending draws to the terminal.
+func (t *Tcell) Flush() error {
+	t.flushLock.Lock()
+	defer t.flushLock.Unlock()
+
+	t.screen.Show()
+	return nil
+}
+
+// Quit ends the program, gives the display back to the terminal, and
+// performs any required cleanup
+func (t *Tcell) Quit() {
+	close(t.events)
+	t.events = nil
+	t.screen.Fini()
+}
+
+// debugf writes to the debug path, if it exists
+func (t *Tcell) debugf(format string, args ...interface{}) {
+	if t.Debug != nil {
+		fmt.Fprintf(t.Debug, "[UI] "+format, args...)
+	}
+}
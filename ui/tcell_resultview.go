@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// tcellStyles maps a backend-neutral tokenColor to its tcell style.
+var tcellStyles = map[tokenColor]tcell.Style{
+	tokenColorDefault: tcell.StyleDefault,
+	tokenColorKey:     tcell.StyleDefault.Foreground(tcell.ColorTeal),
+	tokenColorString:  tcell.StyleDefault.Foreground(tcell.ColorGreen),
+	tokenColorNumber:  tcell.StyleDefault.Foreground(tcell.ColorYellow),
+	tokenColorBool:    tcell.StyleDefault.Foreground(tcell.ColorPurple),
+	tokenColorNull:    tcell.StyleDefault.Foreground(tcell.ColorRed),
+	tokenColorPunct:   tcell.StyleDefault.Foreground(tcell.ColorWhite),
+}
+
+// drawResultView paints the ResultView's current viewport starting on the
+// row below the input line.
+func (t *Tcell) drawResultView() error {
+	w, h := t.screen.Size()
+
+	for y := 1; y < h-1; y++ {
+		for x := 0; x < w; x++ {
+			t.screen.SetContent(x, y, ' ', nil, tcell.StyleDefault)
+		}
+	}
+
+	if t.resultView == nil {
+		return t.Flush()
+	}
+
+	viewportH := h - reservedRows
+	for i := 0; i < viewportH; i++ {
+		lineIdx := t.resultView.scrollY + i
+		if lineIdx >= len(t.resultView.lines) {
+			break
+		}
+		t.drawResultLine(t.resultView.lines[lineIdx], t.resultView.scrollX, 1+i, w)
+	}
+
+	return t.Flush()
+}
+
+// drawResultLine paints a single highlighted line at row y, skipping
+// scrollX columns from its start and clipping at maxWidth.
+func (t *Tcell) drawResultLine(line resultLine, scrollX, y, maxWidth int) {
+	var col, skipped int
+	for _, sp := range line.spans {
+		for _, r := range sp.text {
+			if skipped < scrollX {
+				skipped += runewidth.RuneWidth(r)
+				continue
+			}
+			if col >= maxWidth {
+				return
+			}
+			t.screen.SetContent(col, y, r, nil, tcellStyles[sp.color])
+			col += runewidth.RuneWidth(r)
+		}
+	}
+}
+
+// ScrollDown moves the viewport one line toward the end of the output.
+func (t *Tcell) ScrollDown() error { return t.scrollBy(1) }
+
+// ScrollUp moves the viewport one line toward the start of the output.
+func (t *Tcell) ScrollUp() error { return t.scrollBy(-1) }
+
+// ScrollPageDown moves the viewport down by one full screen height.
+func (t *Tcell) ScrollPageDown() error {
+	_, h := t.screen.Size()
+	return t.scrollBy(h - reservedRows)
+}
+
+// ScrollPageUp moves the viewport up by one full screen height.
+func (t *Tcell) ScrollPageUp() error {
+	_, h := t.screen.Size()
+	return t.scrollBy(-(h - reservedRows))
+}
+
+// ScrollTop jumps the viewport to the first line of output.
+func (t *Tcell) ScrollTop() error {
+	if t.resultView == nil {
+		return nil
+	}
+	t.resultView.scrollToTop()
+	return t.drawResultView()
+}
+
+// ScrollBottom jumps the viewport to the last full screen of output.
+func (t *Tcell) ScrollBottom() error {
+	if t.resultView == nil {
+		return nil
+	}
+	_, h := t.screen.Size()
+	t.resultView.scrollToBottom(h - reservedRows)
+	return t.drawResultView()
+}
+
+// ScrollLeft shifts the viewport left by horizontalScrollStep columns.
+func (t *Tcell) ScrollLeft() error { return t.scrollByX(-horizontalScrollStep) }
+
+// ScrollRight shifts the viewport right by horizontalScrollStep columns.
+func (t *Tcell) ScrollRight() error { return t.scrollByX(horizontalScrollStep) }
+
+func (t *Tcell) scrollBy(delta int) error {
+	if t.resultView == nil {
+		return nil
+	}
+	_, h := t.screen.Size()
+	t.resultView.scrollBy(delta, h-reservedRows)
+	return t.drawResultView()
+}
+
+func (t *Tcell) scrollByX(delta int) error {
+	if t.resultView == nil {
+		return nil
+	}
+	t.resultView.scrollByX(delta)
+	return t.drawResultView()
+}
@@ -0,0 +1,458 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/nsf/termbox-go"
+)
+
+// Termbox draws the jq-live UI via termbox-go. It implements Display.
+type Termbox struct {
+	Debug       io.WriteCloser
+	input       *lineEditor
+	saveMode    bool
+	savePath    string
+	confirmMode bool
+	dirtyInput  bool
+	events      chan (Action)
+	newInput    rune
+	flushLock   sync.Mutex
+	resultView  *ResultView
+	status      Status
+}
+
+const filePrompt = "save to: "
+
+// Start initializes the UI with initialProgram in the input buffer
+func (t *Termbox) Start(initialProgram string) error {
+	if err := termbox.Init(); err != nil {
+		return fmt.Errorf("could not init termbox: %v", err)
+	}
+	termbox.SetCursor(0, 0)
+
+	t.input = newLineEditor(initialProgram)
+
+	// First render
+	t.dirtyInput = true
+
+	return nil
+}
+
+// Program returns the jq program currently in the input buffer.
+func (t *Termbox) Program() string {
+	return t.input.String()
+}
+
+// SaveMode reports whether the UI is currently showing the save-path prompt
+// rather than the jq program input.
+func (t *Termbox) SaveMode() bool {
+	return t.saveMode
+}
+
+// SetSaveMode switches the UI between the jq program input and the
+// save-path prompt.
+func (t *Termbox) SetSaveMode(v bool) {
+	t.saveMode = v
+}
+
+// SavePath returns the path currently entered in the save prompt.
+func (t *Termbox) SavePath() string {
+	return t.savePath
+}
+
+// SetConfirmMode switches the save prompt between entering a path and
+// answering an overwrite-confirmation question.
+func (t *Termbox) SetConfirmMode(v bool) {
+	t.confirmMode = v
+}
+
+// UpdateInput inserts the pending input rune at the cursor.
+func (t *Termbox) UpdateInput() {
+	if t.newInput != 0 {
+		t.input.Insert(t.newInput)
+		t.newInput = 0
+		t.dirtyInput = true
+	}
+}
+
+// UpdateInputBackspace removes the character before the cursor.
+func (t *Termbox) UpdateInputBackspace() {
+	t.input.DeleteBackward()
+	t.dirtyInput = true
+}
+
+// CursorLeft moves the input cursor back one rune.
+func (t *Termbox) CursorLeft() { t.input.MoveLeft() }
+
+// CursorRight moves the input cursor forward one rune.
+func (t *Termbox) CursorRight() { t.input.MoveRight() }
+
+// WordLeft moves the input cursor to the start of the current or previous
+// word.
+func (t *Termbox) WordLeft() { t.input.WordLeft() }
+
+// WordRight moves the input cursor to the end of the current or next word.
+func (t *Termbox) WordRight() { t.input.WordRight() }
+
+// Home moves the input cursor to the start of the program buffer.
+func (t *Termbox) Home() { t.input.Home() }
+
+// End moves the input cursor to the end of the program buffer.
+func (t *Termbox) End() { t.input.End() }
+
+// DeleteForward removes the character under the cursor.
+func (t *Termbox) DeleteForward() { t.input.DeleteForward() }
+
+// KillToEnd removes from the cursor to the end of the program buffer.
+func (t *Termbox) KillToEnd() { t.input.KillToEnd() }
+
+// Yank re-inserts the most recently killed text at the cursor.
+func (t *Termbox) Yank() { t.input.Yank() }
+
+// HistoryPrev replaces the program buffer with the previous history entry.
+func (t *Termbox) HistoryPrev() { t.input.HistoryPrev() }
+
+// HistoryNext replaces the program buffer with the next history entry.
+func (t *Termbox) HistoryNext() { t.input.HistoryNext() }
+
+// UpdateSaveInput appends the new input character to the internal input buffer
+func (t *Termbox) UpdateSaveInput() {
+	if t.newInput != 0 {
+		t.savePath = fmt.Sprintf("%s%s", t.savePath, string(t.newInput))
+		t.newInput = 0
+		t.dirtyInput = true
+	}
+}
+
+// UpdateSaveInputBackspace removes the last character from the input
+func (t *Termbox) UpdateSaveInputBackspace() {
+	if len(t.savePath) == 0 {
+		return
+	}
+
+	t.savePath = t.savePath[0 : len(t.savePath)-1]
+	t.dirtyInput = true
+}
+
+// Events returns a channel of Actions that are sent through the application
+func (t *Termbox) Events() chan (Action) {
+	t.events = make(chan (Action))
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				// Termbox will occasionally crash reading new input into its event
+				// buffers. No idea why, and I don't want to sync time into finding it.
+				// Log and continue listening for events.
+				//
+				// https://github.com/nsf/termbox-go/issues/166
+				// https://github.com/nsf/termbox-go/issues/169
+				t.debugf("termbox events buffer failed: %v\n", r)
+			}
+		}()
+
+		for {
+			switch ev := termbox.PollEvent(); ev.Type {
+			case termbox.EventKey:
+				switch key := ev.Key; key {
+				case termbox.KeyCtrlC:
+					t.events <- ActionExit
+				case termbox.KeyEsc:
+					switch {
+					case t.confirmMode:
+						t.events <- ActionSaveConfirmNo
+					case t.saveMode:
+						t.events <- ActionSaveCancel
+					default:
+						t.events <- ActionExit
+					}
+				// Ctrl+T toggles compact mode; Ctrl+E is reserved below for
+				// emacs-style "end of line" now that the input line supports
+				// cursor movement.
+				case termbox.KeyCtrlT:
+					t.events <- ActionToggleCompact
+				case termbox.KeyCtrlP:
+					t.events <- ActionPrint
+				case termbox.KeyCtrlR:
+					t.events <- ActionToggleRaw
+				case termbox.KeyCtrlS:
+					t.events <- ActionSavePrompt
+				case termbox.KeyCtrlW:
+					if t.saveMode && !t.confirmMode {
+						t.events <- ActionToggleSaveSidecar
+					}
+				case termbox.KeyCtrlA, termbox.KeyHome:
+					if !t.saveMode {
+						t.events <- ActionHome
+					}
+				case termbox.KeyCtrlE, termbox.KeyEnd:
+					if !t.saveMode {
+						t.events <- ActionEnd
+					}
+				case termbox.KeyCtrlD:
+					if !t.saveMode {
+						t.events <- ActionDeleteForward
+					}
+				case termbox.KeyCtrlK:
+					if !t.saveMode {
+						t.events <- ActionKillToEnd
+					}
+				case termbox.KeyCtrlY:
+					if !t.saveMode {
+						t.events <- ActionYank
+					}
+				// termbox-go only ever reports ModAlt on a key event, never
+				// ModCtrl, so Ctrl+Left/Right can't be distinguished from a
+				// plain arrow here the way tcell.go does below. Alt+B/F
+				// (see altActions) is the word-jump binding on this backend.
+				case termbox.KeyArrowLeft:
+					if !t.saveMode {
+						t.events <- ActionCursorLeft
+					}
+				case termbox.KeyArrowRight:
+					if !t.saveMode {
+						t.events <- ActionCursorRight
+					}
+				case termbox.KeyArrowUp:
+					if !t.saveMode {
+						t.events <- ActionHistoryPrev
+					}
+				case termbox.KeyArrowDown:
+					if !t.saveMode {
+						t.events <- ActionHistoryNext
+					}
+				case termbox.KeyPgup:
+					t.events <- ActionScrollPageUp
+				case termbox.KeyPgdn:
+					t.events <- ActionScrollPageDown
+				case termbox.KeyEnter:
+					switch {
+					case t.confirmMode:
+						t.events <- ActionSaveConfirmYes
+					case t.saveMode:
+						t.events <- ActionSaveSubmit
+					default:
+						t.input.Submit()
+						t.events <- ActionSubmit
+					}
+				case termbox.KeyBackspace, termbox.KeyBackspace2:
+					switch {
+					case t.confirmMode:
+					case t.saveMode:
+						t.events <- ActionSavePromptBackspace
+					default:
+						t.events <- ActionInputBackspace
+					}
+				case termbox.KeySpace:
+					if t.confirmMode {
+						break
+					}
+					t.newInput = ' '
+					t.events <- ActionInput
+				default:
+					if t.confirmMode {
+						switch ev.Ch {
+						case 'y', 'Y':
+							t.events <- ActionSaveConfirmYes
+						case 'n', 'N':
+							t.events <- ActionSaveConfirmNo
+						}
+						break
+					}
+
+					if action, ok := altActions[ev.Ch]; ok && ev.Mod&termbox.ModAlt != 0 {
+						t.events <- action
+						break
+					}
+
+					t.debugf("key pressed: %d. Mod: %v\n", ev.Ch, ev.Mod)
+					if ev.Ch != 0 {
+						t.newInput = ev.Ch
+						if t.saveMode {
+							t.events <- ActionSaveInput
+						} else {
+							t.events <- ActionInput
+						}
+					}
+				}
+			}
+		}
+	}()
+
+	return t.events
+}
+
+// RenderInput updates the input display to match the internal buffer,
+// placing the terminal cursor at the input line's logical cursor position.
+func (t *Termbox) RenderInput() error {
+	program := t.input.String()
+	t.debugf("input: %s\n", program)
+
+	scanner := bufio.NewScanner(strings.NewReader(program))
+	scanner.Split(bufio.ScanRunes)
+
+	var x int
+	for scanner.Scan() {
+		r, w := utf8.DecodeRune(scanner.Bytes())
+		termbox.SetCell(x, 0, r, termbox.ColorDefault, termbox.ColorDefault)
+
+		x += w
+	}
+
+	// Clear rest of the input on the row
+	w, _ := termbox.Size()
+	for x := len([]rune(program)); x < w; x++ {
+		termbox.SetCell(x, 0, 0, termbox.ColorDefault, termbox.ColorDefault)
+	}
+
+	termbox.SetCursor(t.input.Cursor(), 0)
+	err := scanner.Err()
+	if err == io.EOF {
+		err = nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("could not process data for printing: %v", err)
+	}
+	return t.Flush()
+}
+
+// RenderFilePrompt switches the UI to the file input
+func (t *Termbox) RenderFilePrompt() error {
+	t.debugf("renderfileprompt: %v\n", t.saveMode)
+	if !t.saveMode {
+		return nil
+	}
+
+	// Clear input row
+	w, _ := termbox.Size()
+	for x := 0; x < w; x++ {
+		termbox.SetCell(x, 0, ' ', termbox.ColorDefault, termbox.ColorDefault)
+	}
+
+	t.debugf("rendering prompt: '%s'\n", filePrompt+t.savePath)
+	prompt := filePrompt + t.savePath
+	scanner := bufio.NewScanner(strings.NewReader(prompt))
+	scanner.Split(bufio.ScanRunes)
+
+	var x int
+	for scanner.Scan() {
+		r, w := utf8.DecodeRune(scanner.Bytes())
+		t.debugf("printing %s\n", string(r))
+		termbox.SetCell(x, 0, r, termbox.ColorDefault, termbox.ColorDefault)
+		x += w
+	}
+	termbox.SetCursor(len(prompt), 0)
+
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return fmt.Errorf("could not print save prompt: %v", err)
+	}
+
+	return t.Flush()
+}
+
+// RenderConfirm draws an arbitrary yes/no confirmation prompt on the input
+// row, e.g. to confirm overwriting an existing file.
+func (t *Termbox) RenderConfirm(prompt string) error {
+	w, _ := termbox.Size()
+	for x := 0; x < w; x++ {
+		termbox.SetCell(x, 0, ' ', termbox.ColorDefault, termbox.ColorDefault)
+	}
+
+	var x int
+	for _, r := range prompt {
+		if x >= w {
+			break
+		}
+		termbox.SetCell(x, 0, r, termbox.ColorDefault, termbox.ColorDefault)
+		x++
+	}
+	termbox.SetCursor(len([]rune(prompt)), 0)
+
+	return t.Flush()
+}
+
+// RenderResults tokenizes the jq output into a line-indexed, syntax
+// highlighted ResultView and draws the first viewport's worth of it. The
+// ResultView is cached on the Termbox so later scrolling redraws reuse the
+// same highlighting work instead of re-reading data.
+func (t *Termbox) RenderResults(data io.Reader) error {
+	rv, err := newResultView(data)
+	if err != nil {
+		return fmt.Errorf("could not process results: %v", err)
+	}
+
+	t.resultView = rv
+	return t.drawResultView()
+}
+
+// Flush prints any unprinted UI changes to the screen
+//
+// termbox-go Flush() is not goroutine safe, so we're protecting access if
+// updates come in very quickly.
+// https://github.com/nsf/termbox-go/issues/113
+func (t *Termbox) Flush() error {
+	t.flushLock.Lock()
+	defer t.flushLock.Unlock()
+
+	return termbox.Flush()
+}
+
+// Size reports termbox's current terminal dimensions in cells.
+func (t *Termbox) Size() (int, int) {
+	return termbox.Size()
+}
+
+// SetStatus records the processor mode and last error for the next
+// RenderStatus call.
+func (t *Termbox) SetStatus(s Status) {
+	t.status = s
+}
+
+// RenderStatus draws the persistent status/footer row: active mode
+// indicators (COMPACT, RAW, SAVE), the last parse error, and the result
+// line count.
+func (t *Termbox) RenderStatus() error {
+	var lineCount int
+	if t.resultView != nil {
+		lineCount = len(t.resultView.lines)
+	}
+	line := statusLine(t.status, t.saveMode, lineCount)
+
+	w, h := termbox.Size()
+	y := h - 1
+
+	var x int
+	for _, r := range line {
+		if x >= w {
+			break
+		}
+		termbox.SetCell(x, y, r, termbox.ColorDefault, termbox.ColorDefault)
+		x++
+	}
+	for ; x < w; x++ {
+		termbox.SetCell(x, y, ' ', termbox.ColorDefault, termbox.ColorDefault)
+	}
+
+	return t.Flush()
+}
+
+// Quit ends the program, gives the display back to the terminal, and performs
+// any required cleanup
+func (t *Termbox) Quit() {
+	close(t.events)
+	t.events = nil
+	termbox.Close()
+}
+
+// debugf writes to the debug path, if it exists
+func (t *Termbox) debugf(format string, args ...interface{}) {
+	if t.Debug != nil {
+		fmt.Fprintf(t.Debug, "[UI] "+format, args...)
+	}
+}
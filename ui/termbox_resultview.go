@@ -0,0 +1,120 @@
+package ui
+
+import "github.com/nsf/termbox-go"
+
+// termboxColors maps a backend-neutral tokenColor to its termbox color.
+var termboxColors = map[tokenColor]termbox.Attribute{
+	tokenColorDefault: termbox.ColorDefault,
+	tokenColorKey:     termbox.ColorCyan,
+	tokenColorString:  termbox.ColorGreen,
+	tokenColorNumber:  termbox.ColorYellow,
+	tokenColorBool:    termbox.ColorMagenta,
+	tokenColorNull:    termbox.ColorRed,
+	tokenColorPunct:   termbox.ColorWhite,
+}
+
+// drawResultView paints the ResultView's current viewport starting on the
+// row below the input line.
+func (t *Termbox) drawResultView() error {
+	w, h := termbox.Size()
+
+	for y := 1; y < h-1; y++ {
+		for x := 0; x < w; x++ {
+			termbox.SetCell(x, y, 0, termbox.ColorDefault, termbox.ColorDefault)
+		}
+	}
+
+	if t.resultView == nil {
+		return t.Flush()
+	}
+
+	viewportH := h - reservedRows
+	for i := 0; i < viewportH; i++ {
+		lineIdx := t.resultView.scrollY + i
+		if lineIdx >= len(t.resultView.lines) {
+			break
+		}
+		drawResultLine(t.resultView.lines[lineIdx], t.resultView.scrollX, 1+i, w)
+	}
+
+	return t.Flush()
+}
+
+// drawResultLine paints a single highlighted line at row y, skipping
+// scrollX columns from its start and clipping at maxWidth.
+func drawResultLine(line resultLine, scrollX, y, maxWidth int) {
+	var col, skipped int
+	for _, sp := range line.spans {
+		for _, r := range sp.text {
+			if skipped < scrollX {
+				skipped++
+				continue
+			}
+			if col >= maxWidth {
+				return
+			}
+			termbox.SetCell(col, y, r, termboxColors[sp.color], termbox.ColorDefault)
+			col++
+		}
+	}
+}
+
+// ScrollDown moves the viewport one line toward the end of the output.
+func (t *Termbox) ScrollDown() error { return t.scrollBy(1) }
+
+// ScrollUp moves the viewport one line toward the start of the output.
+func (t *Termbox) ScrollUp() error { return t.scrollBy(-1) }
+
+// ScrollPageDown moves the viewport down by one full screen height.
+func (t *Termbox) ScrollPageDown() error {
+	_, h := termbox.Size()
+	return t.scrollBy(h - reservedRows)
+}
+
+// ScrollPageUp moves the viewport up by one full screen height.
+func (t *Termbox) ScrollPageUp() error {
+	_, h := termbox.Size()
+	return t.scrollBy(-(h - reservedRows))
+}
+
+// ScrollTop jumps the viewport to the first line of output.
+func (t *Termbox) ScrollTop() error {
+	if t.resultView == nil {
+		return nil
+	}
+	t.resultView.scrollToTop()
+	return t.drawResultView()
+}
+
+// ScrollBottom jumps the viewport to the last full screen of output.
+func (t *Termbox) ScrollBottom() error {
+	if t.resultView == nil {
+		return nil
+	}
+	_, h := termbox.Size()
+	t.resultView.scrollToBottom(h - reservedRows)
+	return t.drawResultView()
+}
+
+// ScrollLeft shifts the viewport left by horizontalScrollStep columns.
+func (t *Termbox) ScrollLeft() error { return t.scrollByX(-horizontalScrollStep) }
+
+// ScrollRight shifts the viewport right by horizontalScrollStep columns.
+func (t *Termbox) ScrollRight() error { return t.scrollByX(horizontalScrollStep) }
+
+func (t *Termbox) scrollBy(delta int) error {
+	if t.resultView == nil {
+		return nil
+	}
+	_, h := termbox.Size()
+	t.resultView.scrollBy(delta, h-reservedRows)
+	return t.drawResultView()
+}
+
+func (t *Termbox) scrollByX(delta int) error {
+	if t.resultView == nil {
+		return nil
+	}
+	t.resultView.scrollByX(delta)
+	return t.drawResultView()
+}